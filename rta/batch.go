@@ -0,0 +1,117 @@
+package rta
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNilSubscription is returned by UnsubscribeMany and UnsubscribeManyWithOptions for any
+// nil entry in subs, such as one left by a failed SubscribeMany call, instead of
+// dereferencing it.
+var ErrNilSubscription = errors.New("xsapi/rta: nil subscription")
+
+// SubscribeManyOptions configures Conn.SubscribeManyWithOptions and
+// Conn.UnsubscribeManyWithOptions.
+type SubscribeManyOptions struct {
+	// MaxInFlight caps the number of handshakes dispatched concurrently. Zero means no cap.
+	MaxInFlight int
+}
+
+// SubscribeMany attempts to subscribe to every resource URI in resourceURIs concurrently,
+// under a single parent ctx: cancelling ctx cancels every handshake still in flight. The
+// returned slices have the same length as resourceURIs and preserve its ordering; a
+// resourceURIs[i] that failed to subscribe has a nil entry in the returned subscriptions and
+// its error at the same index.
+func (c *Conn) SubscribeMany(ctx context.Context, resourceURIs []string) ([]*Subscription, []error) {
+	return c.SubscribeManyWithOptions(ctx, resourceURIs, SubscribeManyOptions{})
+}
+
+// SubscribeManyWithOptions is like SubscribeMany, but allows capping the number of
+// handshakes dispatched concurrently through opts.
+func (c *Conn) SubscribeManyWithOptions(ctx context.Context, resourceURIs []string, opts SubscribeManyOptions) ([]*Subscription, []error) {
+	subs := make([]*Subscription, len(resourceURIs))
+	errs := make([]error, len(resourceURIs))
+	inFlight := newInFlightLimiter(opts.MaxInFlight)
+
+	var wg sync.WaitGroup
+	for i, resourceURI := range resourceURIs {
+		wg.Add(1)
+		go func(i int, resourceURI string) {
+			defer wg.Done()
+			release, err := inFlight.acquire(ctx)
+			defer release()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			subs[i], errs[i] = c.Subscribe(ctx, resourceURI)
+		}(i, resourceURI)
+	}
+	wg.Wait()
+	return subs, errs
+}
+
+// UnsubscribeMany attempts to unsubscribe every Subscription in subs concurrently, under a
+// single parent ctx: cancelling ctx cancels every handshake still in flight. The returned
+// slice has the same length as subs and preserves its ordering. A nil entry in subs, such as
+// one left by a failed SubscribeMany call, is reported as ErrNilSubscription rather than
+// dereferenced, so SubscribeMany's result can be passed to UnsubscribeMany directly.
+func (c *Conn) UnsubscribeMany(ctx context.Context, subs []*Subscription) []error {
+	return c.UnsubscribeManyWithOptions(ctx, subs, SubscribeManyOptions{})
+}
+
+// UnsubscribeManyWithOptions is like UnsubscribeMany, but allows capping the number of
+// handshakes dispatched concurrently through opts.
+func (c *Conn) UnsubscribeManyWithOptions(ctx context.Context, subs []*Subscription, opts SubscribeManyOptions) []error {
+	errs := make([]error, len(subs))
+	inFlight := newInFlightLimiter(opts.MaxInFlight)
+
+	var wg sync.WaitGroup
+	for i, sub := range subs {
+		wg.Add(1)
+		go func(i int, sub *Subscription) {
+			defer wg.Done()
+			if sub == nil {
+				errs[i] = ErrNilSubscription
+				return
+			}
+			release, err := inFlight.acquire(ctx)
+			defer release()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = c.Unsubscribe(ctx, sub)
+		}(i, sub)
+	}
+	wg.Wait()
+	return errs
+}
+
+// inFlightLimiter bounds how many handshakes SubscribeManyWithOptions and
+// UnsubscribeManyWithOptions dispatch concurrently.
+type inFlightLimiter struct {
+	sem chan struct{}
+}
+
+func newInFlightLimiter(max int) *inFlightLimiter {
+	if max <= 0 {
+		return &inFlightLimiter{}
+	}
+	return &inFlightLimiter{sem: make(chan struct{}, max)}
+}
+
+// acquire blocks until a slot is free or ctx is done, whichever comes first. The returned
+// release must always be called, even when err is non-nil.
+func (l *inFlightLimiter) acquire(ctx context.Context) (release func(), err error) {
+	if l.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem }, nil
+	case <-ctx.Done():
+		return func() {}, ctx.Err()
+	}
+}
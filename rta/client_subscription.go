@@ -0,0 +1,106 @@
+package rta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+)
+
+// ErrSubscriptionQueueOverflow is delivered on ClientSubscription.Err when the channel
+// passed to Conn.SubscribeChan could not be drained in time and the subscription had to be
+// torn down to protect the rest of the connection.
+var ErrSubscriptionQueueOverflow = errors.New("xsapi/rta: subscription queue overflow")
+
+// ClientSubscription represents a subscription to a resource URI whose events are delivered
+// on a user-provided channel instead of through a SubscriptionHandler. A ClientSubscription
+// is obtained through Conn.SubscribeChan, inspired by the subscription model of
+// go-ethereum's rpc client.
+type ClientSubscription struct {
+	sub  *Subscription
+	conn *Conn
+
+	quit chan struct{}
+	err  chan error
+	once sync.Once
+}
+
+// Err returns a channel that receives at most one error describing why the subscription
+// ended: the connection closing, the service reporting an error status, or
+// ErrSubscriptionQueueOverflow if the channel passed to SubscribeChan could not keep up.
+// The channel is closed once the subscription has fully ended, including after a clean call
+// to Unsubscribe.
+func (cs *ClientSubscription) Err() <-chan error {
+	return cs.err
+}
+
+// Unsubscribe ends the subscription and closes the channel returned by Err without sending
+// an error on it.
+func (cs *ClientSubscription) Unsubscribe() {
+	cs.once.Do(func() {
+		close(cs.quit)
+		_ = cs.conn.Unsubscribe(context.Background(), cs.sub)
+		close(cs.err)
+	})
+}
+
+// fail tears down the subscription and delivers err on Err, unless Unsubscribe has already
+// been called for it.
+func (cs *ClientSubscription) fail(err error) {
+	cs.once.Do(func() {
+		close(cs.quit)
+		cs.err <- err
+		close(cs.err)
+	})
+}
+
+// watchClosed fails the subscription once the underlying Conn is closed, so callers relying
+// on Err are notified instead of silently losing events.
+func (cs *ClientSubscription) watchClosed() {
+	select {
+	case <-cs.conn.closed:
+		cs.fail(net.ErrClosed)
+	case <-cs.quit:
+	}
+}
+
+// chanSubscriptionHandler implements SubscriptionHandler by forwarding events onto a
+// user-provided channel.
+type chanSubscriptionHandler struct {
+	ch chan<- json.RawMessage
+	cs *ClientSubscription
+}
+
+func (h *chanSubscriptionHandler) HandleEvent(custom json.RawMessage) {
+	select {
+	case h.ch <- custom:
+	case <-h.cs.quit:
+	}
+}
+
+// HandleError implements SubscriptionErrorHandler, surfacing a queue overflow under
+// OverflowPolicy Disconnect, or a failed resubscription after a reconnect, through cs.Err.
+func (h *chanSubscriptionHandler) HandleError(err error) {
+	h.cs.fail(err)
+}
+
+// SubscribeChan attempts to subscribe with the specific resource URI, with the
+// [context.Context] to be used during the handshake, delivering subsequent events on ch
+// rather than through a SubscriptionHandler. The returned ClientSubscription's Err channel
+// surfaces the reason the subscription ended; see Err for details.
+func (c *Conn) SubscribeChan(ctx context.Context, resourceURI string, ch chan<- json.RawMessage) (*ClientSubscription, error) {
+	sub, err := c.SubscribeWithOptions(ctx, resourceURI, SubscribeOptions{Overflow: Disconnect})
+	if err != nil {
+		return nil, err
+	}
+	cs := &ClientSubscription{
+		sub:  sub,
+		conn: c,
+		quit: make(chan struct{}),
+		err:  make(chan error, 1),
+	}
+	sub.Handle(&chanSubscriptionHandler{ch: ch, cs: cs})
+	go cs.watchClosed()
+	return cs, nil
+}
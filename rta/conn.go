@@ -24,7 +24,8 @@ import (
 // SubscriptionHandlers are useful to handle any events that may occur in the subscriptions
 // controlled by Conn, and can be stored atomically to a Subscription from [Subscription.Handle].
 type Conn struct {
-	conn *websocket.Conn
+	conn   *websocket.Conn
+	connMu sync.RWMutex
 
 	sequences  [operationCapacity]atomic.Uint32
 	expected   [operationCapacity]map[uint32]chan<- *handshake
@@ -35,14 +36,54 @@ type Conn struct {
 
 	log *slog.Logger
 
+	// reconnect and dial are configured through Dialer. When reconnect is true, a read loop
+	// terminating with a non-normal error triggers doReconnect instead of closing c.
+	reconnect bool
+	dial      func(ctx context.Context) (*websocket.Conn, error)
+
+	// maxSubscriptions and maxPendingHandshakes are configured through Dialer. Zero means no
+	// limit.
+	maxSubscriptions     uint32
+	maxPendingHandshakes uint32
+
+	stateCh   chan ConnState
+	stateOnce sync.Once
+
 	once   sync.Once
 	closed chan struct{}
 }
 
+// getConn returns the websocket connection currently backing c.
+func (c *Conn) getConn() *websocket.Conn {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	return c.conn
+}
+
+// setConn swaps the websocket connection backing c, used after a successful reconnect.
+func (c *Conn) setConn(conn *websocket.Conn) {
+	c.connMu.Lock()
+	c.conn = conn
+	c.connMu.Unlock()
+}
+
 // Subscribe attempts to subscribe with the specific resource URI, with the [context.Context]
 // to be used during the handshake. A Subscription may be returned, which contains an ID
-// and Custom data as the result of handshake.
+// assigned by Conn and Custom data as the result of handshake. Its event queue is configured with
+// DefaultQueueCapacity and DropOldest; use SubscribeWithOptions to customise it.
 func (c *Conn) Subscribe(ctx context.Context, resourceURI string) (*Subscription, error) {
+	return c.SubscribeWithOptions(ctx, resourceURI, SubscribeOptions{})
+}
+
+// SubscribeWithOptions is like Subscribe, but allows customising the capacity and overflow
+// behaviour of the subscription's event queue through opts.
+func (c *Conn) SubscribeWithOptions(ctx context.Context, resourceURI string, opts SubscribeOptions) (*Subscription, error) {
+	if err := c.admitSubscription(); err != nil {
+		return nil, err
+	}
+	if err := c.admitHandshake(operationSubscribe); err != nil {
+		return nil, err
+	}
 	sequence := c.sequences[operationSubscribe].Add(1)
 	hand, err := c.shake(operationSubscribe, sequence, []any{resourceURI})
 	if err != nil {
@@ -59,14 +100,15 @@ func (c *Conn) Subscribe(ctx context.Context, resourceURI string) (*Subscription
 					Index:   1,
 				}
 			}
-			sub := &Subscription{}
-			if err := json.Unmarshal(h.payload[0], &sub.ID); err != nil {
+			sub := newSubscription(opts, c.log)
+			sub.resourceURI = resourceURI
+			if err := json.Unmarshal(h.payload[0], &sub.serverID); err != nil {
 				return nil, fmt.Errorf("decode subscription ConnectionID: %w", err)
 			}
 			sub.Custom = h.payload[1]
 
 			c.subscriptionsMu.Lock()
-			c.subscriptions[sub.ID] = sub
+			c.subscriptions[sub.serverID] = sub
 			c.subscriptionsMu.Unlock()
 			return sub, nil
 		default:
@@ -82,8 +124,11 @@ func (c *Conn) Subscribe(ctx context.Context, resourceURI string) (*Subscription
 // Unsubscribe attempts to unsubscribe with a Subscription associated with an ID, with
 // the [context.Context] to be used during the handshake. An error may be returned.
 func (c *Conn) Unsubscribe(ctx context.Context, sub *Subscription) error {
+	if err := c.admitHandshake(operationUnsubscribe); err != nil {
+		return err
+	}
 	sequence := c.sequences[operationUnsubscribe].Add(1)
-	hand, err := c.shake(operationUnsubscribe, sequence, []any{sub.ID})
+	hand, err := c.shake(operationUnsubscribe, sequence, []any{sub.serverID})
 	if err != nil {
 		return err
 	}
@@ -93,6 +138,10 @@ func (c *Conn) Unsubscribe(ctx context.Context, sub *Subscription) error {
 		if h.status != StatusOK {
 			return unexpectedStatusCode(h.status, h.payload)
 		}
+		c.subscriptionsMu.Lock()
+		delete(c.subscriptions, sub.serverID)
+		c.subscriptionsMu.Unlock()
+		sub.teardown(nil)
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
@@ -103,12 +152,30 @@ func (c *Conn) Unsubscribe(ctx context.Context, sub *Subscription) error {
 
 // Subscription represents a subscription contracted with the resource URI available through
 // the real-time activity service. A Subscription may be contracted via Conn.Subscribe.
+//
+// ID is assigned once, when the Subscription is created, and stays stable for its caller
+// even if Conn transparently reconnects and re-issues the subscription under a new
+// server-assigned ID.
 type Subscription struct {
 	ID     uint32
 	Custom json.RawMessage
 
 	h  SubscriptionHandler
 	mu sync.Mutex
+
+	resourceURI string
+	serverID    uint32
+
+	events    chan json.RawMessage
+	policy    OverflowPolicy
+	dropped   atomic.Uint64
+	done      chan struct{}
+	closeOnce sync.Once
+	log       *slog.Logger
+
+	dedupMu    sync.Mutex
+	recent     [dedupWindow]uint64
+	recentNext int
 }
 
 func (s *Subscription) Handle(h SubscriptionHandler) {
@@ -126,6 +193,10 @@ func (s *Subscription) handler() SubscriptionHandler {
 	return s.h
 }
 
+// A SubscriptionHandler is called by Conn with events for a single Subscription, in the
+// order they were received from the service and with duplicates already filtered out.
+// Events for different Subscriptions may still be delivered concurrently, so a
+// SubscriptionHandler shared between several Subscriptions must be safe for concurrent use.
 type SubscriptionHandler interface {
 	HandleEvent(custom json.RawMessage)
 }
@@ -138,23 +209,44 @@ func (NopSubscriptionHandler) HandleEvent(json.RawMessage) {}
 // used as no context perceived by the parent goroutine should be used to a websocket method
 // to avoid closing the connection if it has cancelled or exceeded a deadline.
 func (c *Conn) write(typ uint32, payload []any) error {
-	return wsjson.Write(context.Background(), c.conn, append([]any{typ}, payload...))
+	return wsjson.Write(context.Background(), c.getConn(), append([]any{typ}, payload...))
 }
 
 // read goes as a background goroutine of Conn, reading a JSON array from the websocket
-// connection and decoding a header needed to indicate which message should be handled.
+// connection and decoding a header needed to indicate which message should be handled. If c
+// has reconnect enabled, a read error triggers doReconnect instead of closing c.
 func (c *Conn) read() {
+	for {
+		if err := c.readOnce(); err != nil {
+			if !c.reconnect || websocket.CloseStatus(err) == websocket.StatusNormalClosure || !c.doReconnect() {
+				_ = c.Close()
+				return
+			}
+		}
+	}
+}
+
+// readOnce reads and dispatches messages from the current websocket connection until it
+// errors, returning that error. Event messages are handled synchronously, in the order they
+// are read off the socket, so that Subscription.enqueue is always called in receive order;
+// handshake responses, which carry their own sequence number and don't need that ordering,
+// are still handled off of a goroutine so a slow caller awaiting one can't stall the loop.
+func (c *Conn) readOnce() error {
+	conn := c.getConn()
 	for {
 		var payload []json.RawMessage
-		if err := wsjson.Read(context.Background(), c.conn, &payload); err != nil {
-			_ = c.Close()
-			return
+		if err := wsjson.Read(context.Background(), conn, &payload); err != nil {
+			return err
 		}
 		typ, err := readHeader(payload)
 		if err != nil {
 			c.log.Error("error reading header", internal.ErrAttr(err))
 			continue
 		}
+		if typ == typeEvent {
+			c.handleMessage(typ, payload[1:])
+			continue
+		}
 		go c.handleMessage(typ, payload[1:])
 	}
 }
@@ -163,7 +255,13 @@ func (c *Conn) read() {
 func (c *Conn) Close() (err error) {
 	c.once.Do(func() {
 		close(c.closed)
-		err = c.conn.Close(websocket.StatusNormalClosure, "")
+		c.subscriptionsMu.RLock()
+		for _, sub := range c.subscriptions {
+			sub.teardown(nil)
+		}
+		c.subscriptionsMu.RUnlock()
+		c.notifyState(StateClosed)
+		err = c.getConn().Close(websocket.StatusNormalClosure, "")
 	})
 	return err
 }
@@ -195,11 +293,11 @@ func (c *Conn) handleMessage(typ uint32, payload []json.RawMessage) {
 		if err := json.Unmarshal(payload[0], &subscriptionID); err != nil {
 			c.log.Error("error decoding subscription ID", internal.ErrAttr(err))
 		}
-		c.subscriptionsMu.Lock()
-		defer c.subscriptionsMu.Unlock()
+		c.subscriptionsMu.RLock()
 		sub, ok := c.subscriptions[subscriptionID]
+		c.subscriptionsMu.RUnlock()
 		if ok {
-			go sub.handler().HandleEvent(payload[1])
+			sub.enqueue(payload[1])
 		}
 		c.log.Debug("received event", slog.Group("message", "type", typ, "custom", payload[0]))
 	default:
@@ -0,0 +1,42 @@
+package rta
+
+import (
+	"encoding/json"
+	"hash/fnv"
+)
+
+// dedupWindow is the number of recently delivered events remembered per Subscription to
+// detect and skip re-delivery.
+const dedupWindow = 32
+
+// alreadyDelivered reports whether an event hashing to h was already handed off to s's
+// handler recently. It protects against the RTA service re-sending events it had already
+// delivered, which can happen after a transient server-side hiccup. It does not itself
+// record h; callers must call markDelivered once the event is actually handed off, not
+// merely received.
+func (s *Subscription) alreadyDelivered(h uint64) bool {
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+	for _, p := range s.recent {
+		if p == h {
+			return true
+		}
+	}
+	return false
+}
+
+// markDelivered records h in s's dedup window. It must only be called for an event that was
+// actually handed off for delivery, not one dropped by an OverflowPolicy.
+func (s *Subscription) markDelivered(h uint64) {
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+	s.recent[s.recentNext] = h
+	s.recentNext = (s.recentNext + 1) % dedupWindow
+}
+
+// hashEvent hashes an event payload for use in Subscription's dedup window.
+func hashEvent(payload json.RawMessage) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(payload)
+	return h.Sum64()
+}
@@ -0,0 +1,40 @@
+package rta
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// TestSubscriptionEnqueueDedupesRepeatedPayload asserts that re-delivering an event already
+// handed off to the handler is silently dropped instead of being delivered twice, as can
+// happen when the RTA service re-sends pending events after a transient hiccup. Because
+// Conn now calls enqueue synchronously and in receive order (see readOnce), the resend and
+// the events around it are also guaranteed to be considered by alreadyDelivered/markDelivered
+// in that same order.
+func TestSubscriptionEnqueueDedupesRepeatedPayload(t *testing.T) {
+	sub := newSubscription(SubscribeOptions{QueueCapacity: 8}, slog.Default())
+	h := &recordingHandler{}
+	sub.Handle(h)
+
+	sub.enqueue(json.RawMessage(`"a"`))
+	sub.enqueue(json.RawMessage(`"b"`))
+	sub.enqueue(json.RawMessage(`"a"`)) // resend of an already-delivered event
+
+	deadline := time.Now().Add(time.Second)
+	for len(h.snapshot()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d events, want 2", len(h.snapshot()))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Give the resend a chance to land before asserting that it didn't.
+	time.Sleep(10 * time.Millisecond)
+	want := []string{`"a"`, `"b"`}
+	got := h.snapshot()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package rta
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/coder/websocket"
+)
+
+// rtaWebSocketURL is the real-time activity service's websocket endpoint.
+const rtaWebSocketURL = "wss://rta.xboxlive.com/connect"
+
+// Dialer establishes a Conn with the real-time activity service.
+type Dialer struct {
+	// Log receives diagnostic output from a dialed Conn. A nil Log defaults to
+	// slog.Default().
+	Log *slog.Logger
+
+	// Reconnect enables automatic reconnect with transparent resubscription when a dialed
+	// Conn's websocket read loop terminates with a non-normal error.
+	Reconnect bool
+
+	// MaxSubscriptions caps the number of live subscriptions a dialed Conn may hold at once.
+	// Zero means no limit.
+	MaxSubscriptions uint32
+	// MaxPendingHandshakes caps the number of handshakes of a single operation that may be
+	// outstanding at once on a dialed Conn. Zero means no limit.
+	MaxPendingHandshakes uint32
+}
+
+// Dial establishes a Conn with the real-time activity service, authenticated with token, an
+// authorization token that relies on the party 'https://xboxlive.com/'. ConnState reports
+// StateConnecting and, once established, StateConnected for this initial connection too, not
+// just for later reconnects.
+func (d Dialer) Dial(ctx context.Context, token string) (*Conn, error) {
+	dial := func(ctx context.Context) (*websocket.Conn, error) {
+		conn, _, err := websocket.Dial(ctx, rtaWebSocketURL, &websocket.DialOptions{
+			HTTPHeader: http.Header{"Authorization": {token}},
+		})
+		return conn, err
+	}
+
+	log := d.Log
+	if log == nil {
+		log = slog.Default()
+	}
+
+	c := &Conn{
+		subscriptions:        make(map[uint32]*Subscription),
+		log:                  log,
+		reconnect:            d.Reconnect,
+		dial:                 dial,
+		maxSubscriptions:     d.MaxSubscriptions,
+		maxPendingHandshakes: d.MaxPendingHandshakes,
+		closed:               make(chan struct{}),
+	}
+	for op := range c.expected {
+		c.expected[op] = make(map[uint32]chan<- *handshake)
+	}
+
+	c.notifyState(StateConnecting)
+	conn, err := dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.setConn(conn)
+	c.notifyState(StateConnected)
+
+	go c.read()
+	return c, nil
+}
@@ -0,0 +1,43 @@
+package rta
+
+import "errors"
+
+// ErrSubscriptionLimit is returned by Subscribe and SubscribeWithOptions when Conn already
+// has MaxSubscriptions live subscriptions, a bound configured through Dialer. It is returned
+// before a handshake is issued, so a caller hitting it never waits on the service to reject
+// it.
+var ErrSubscriptionLimit = errors.New("xsapi/rta: subscription limit reached")
+
+// ErrHandshakeLimit is returned by Subscribe and Unsubscribe when the operation already has
+// MaxPendingHandshakes handshakes awaiting a response, a bound configured through Dialer.
+var ErrHandshakeLimit = errors.New("xsapi/rta: pending handshake limit reached")
+
+// admitSubscription reports ErrSubscriptionLimit if c already has MaxSubscriptions live
+// subscriptions.
+func (c *Conn) admitSubscription() error {
+	if c.maxSubscriptions == 0 {
+		return nil
+	}
+	c.subscriptionsMu.RLock()
+	n := len(c.subscriptions)
+	c.subscriptionsMu.RUnlock()
+	if uint32(n) >= c.maxSubscriptions {
+		return ErrSubscriptionLimit
+	}
+	return nil
+}
+
+// admitHandshake reports ErrHandshakeLimit if op already has MaxPendingHandshakes handshakes
+// awaiting a response.
+func (c *Conn) admitHandshake(op uint32) error {
+	if c.maxPendingHandshakes == 0 {
+		return nil
+	}
+	c.expectedMu.RLock()
+	n := len(c.expected[op])
+	c.expectedMu.RUnlock()
+	if uint32(n) >= c.maxPendingHandshakes {
+		return ErrHandshakeLimit
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package rta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/df-mc/go-xsapi/internal"
+)
+
+// ConnState describes a transition in the state of the websocket connection backing a Conn.
+type ConnState int
+
+const (
+	// StateConnecting is sent while a Conn is being established for the first time.
+	StateConnecting ConnState = iota
+	// StateConnected is sent once a Conn's websocket connection is usable, including after
+	// a successful reconnect.
+	StateConnected
+	// StateReconnecting is sent when the read loop has observed a non-normal disconnect and
+	// is redialing with backoff.
+	StateReconnecting
+	// StateClosed is sent once a Conn has been closed and will not reconnect again.
+	StateClosed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("ConnState(%d)", int(s))
+	}
+}
+
+// reconnectBackoffInitial and reconnectBackoffMax bound the exponential backoff applied
+// between redial attempts while a Conn with reconnect enabled is reconnecting.
+const (
+	reconnectBackoffInitial = 500 * time.Millisecond
+	reconnectBackoffMax     = 30 * time.Second
+)
+
+// resubscribeTimeout bounds how long a single resubscribe handshake may take before it is
+// abandoned and its Subscription torn down.
+const resubscribeTimeout = 10 * time.Second
+
+// ConnState returns a channel receiving the state transitions of c's websocket connection.
+// Every Conn sends StateConnecting and StateConnected when dialed and StateClosed once
+// closed; only a Conn established with reconnect enabled through Dialer also sends
+// StateReconnecting. The channel buffers 16 states; a transition sent while it is full is
+// dropped. The channel is never closed.
+func (c *Conn) ConnState() <-chan ConnState {
+	c.ensureStateCh()
+	return c.stateCh
+}
+
+func (c *Conn) ensureStateCh() {
+	c.stateOnce.Do(func() {
+		c.stateCh = make(chan ConnState, 16)
+	})
+}
+
+// notifyState sends state on c's state channel without blocking if nobody is reading it.
+func (c *Conn) notifyState(state ConnState) {
+	c.ensureStateCh()
+	select {
+	case c.stateCh <- state:
+	default:
+	}
+}
+
+// doReconnect redials c's websocket connection with exponential backoff and re-issues every
+// live Subscription against the new socket, keeping each Subscription's external ID stable
+// while remapping the server-assigned ID backing it. It reports whether a new connection was
+// established; it returns false once c has been closed.
+func (c *Conn) doReconnect() bool {
+	select {
+	case <-c.closed:
+		return false
+	default:
+	}
+	c.notifyState(StateReconnecting)
+	backoff := reconnectBackoffInitial
+	for {
+		select {
+		case <-c.closed:
+			return false
+		default:
+		}
+		conn, err := c.dial(context.Background())
+		if err != nil {
+			c.log.Debug("reconnect attempt failed", internal.ErrAttr(err))
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > reconnectBackoffMax {
+				backoff = reconnectBackoffMax
+			}
+			continue
+		}
+		c.setConn(conn)
+		// Handshake responses are only read once the read loop resumes on the new connection,
+		// so the subscriptions are re-issued off of it.
+		go func() {
+			c.resubscribeAll()
+			c.notifyState(StateConnected)
+		}()
+		return true
+	}
+}
+
+// resubscribeResult records the outcome of re-issuing a single Subscription's handshake, so
+// c.subscriptions can be updated once every attempt has finished without holding
+// subscriptionsMu across the blocking handshakes themselves.
+type resubscribeResult struct {
+	sub      *Subscription
+	oldID    uint32
+	resolved bool
+}
+
+// resubscribeAll re-issues every Subscription currently tracked by c against its current
+// websocket connection. A Subscription whose resubscription fails or times out is torn down
+// and its error surfaced, the same way a queue overflow under OverflowPolicy Disconnect
+// would be. subscriptionsMu is only held to snapshot the subscriptions to resubscribe and
+// again to install the results, not across the handshakes themselves, so a service that
+// never acks one of them cannot wedge unrelated Subscribe or Unsubscribe calls.
+func (c *Conn) resubscribeAll() {
+	c.subscriptionsMu.RLock()
+	subs := make([]*Subscription, 0, len(c.subscriptions))
+	for _, sub := range c.subscriptions {
+		subs = append(subs, sub)
+	}
+	c.subscriptionsMu.RUnlock()
+
+	results := make([]resubscribeResult, len(subs))
+	for i, sub := range subs {
+		oldID := sub.serverID
+		if err := c.resubscribeOne(sub); err != nil {
+			c.log.Error("resubscribe failed", internal.ErrAttr(err), "resourceURI", sub.resourceURI)
+			sub.teardown(err)
+			results[i] = resubscribeResult{sub: sub, oldID: oldID}
+			continue
+		}
+		results[i] = resubscribeResult{sub: sub, oldID: oldID, resolved: true}
+	}
+
+	c.subscriptionsMu.Lock()
+	// Every old ID is removed before any new one is installed, as a new server-assigned ID
+	// may equal the old ID of another Subscription.
+	for _, r := range results {
+		if c.subscriptions[r.oldID] == r.sub {
+			delete(c.subscriptions, r.oldID)
+		}
+	}
+	for _, r := range results {
+		if r.resolved {
+			c.subscriptions[r.sub.serverID] = r.sub
+		}
+	}
+	c.subscriptionsMu.Unlock()
+}
+
+// resubscribeOne re-issues the handshake for sub's resourceURI against c's current websocket
+// connection, bounded by resubscribeTimeout, and updates sub's internal server-assigned ID
+// and Custom payload in place.
+func (c *Conn) resubscribeOne(sub *Subscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), resubscribeTimeout)
+	defer cancel()
+
+	sequence := c.sequences[operationSubscribe].Add(1)
+	hand, err := c.shake(operationSubscribe, sequence, []any{sub.resourceURI})
+	if err != nil {
+		return err
+	}
+	defer c.release(operationSubscribe, sequence)
+	select {
+	case h := <-hand:
+		if h.status != StatusOK {
+			return unexpectedStatusCode(h.status, h.payload)
+		}
+		if len(h.payload) < 2 {
+			return &OutOfRangeError{Payload: h.payload, Index: 1}
+		}
+		if err := json.Unmarshal(h.payload[0], &sub.serverID); err != nil {
+			return fmt.Errorf("decode subscription ConnectionID: %w", err)
+		}
+		sub.Custom = h.payload[1]
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.closed:
+		return net.ErrClosed
+	}
+}
@@ -0,0 +1,149 @@
+package rta
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync/atomic"
+)
+
+// DefaultQueueCapacity is the number of events buffered for a Subscription before its
+// OverflowPolicy takes effect.
+const DefaultQueueCapacity = 32
+
+// OverflowPolicy controls what Conn does when a Subscription's event queue is full and
+// another event for it arrives before the previously queued ones have been dispatched.
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest queued event to make room for the new one. It is the
+	// zero value of OverflowPolicy.
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the incoming event, leaving the queue as it was.
+	DropNewest
+	// Disconnect tears down the subscription and surfaces ErrSubscriptionQueueOverflow
+	// through SubscriptionErrorHandler or ClientSubscription.Err, whichever applies to it.
+	Disconnect
+)
+
+// SubscribeOptions configures the event queue of a Subscription created through
+// Conn.SubscribeWithOptions.
+type SubscribeOptions struct {
+	// QueueCapacity is the number of events buffered for the subscription before Overflow
+	// takes effect. Zero uses DefaultQueueCapacity.
+	QueueCapacity int
+	// Overflow is the policy applied once the queue is full. The zero value is DropOldest.
+	Overflow OverflowPolicy
+}
+
+func (o SubscribeOptions) withDefaults() SubscribeOptions {
+	if o.QueueCapacity <= 0 {
+		o.QueueCapacity = DefaultQueueCapacity
+	}
+	return o
+}
+
+// SubscriptionErrorHandler may optionally be implemented by a SubscriptionHandler to be
+// notified when Conn tears down its Subscription because of a queue overflow under
+// OverflowPolicy Disconnect, or because re-issuing it after a reconnect failed.
+type SubscriptionErrorHandler interface {
+	HandleError(err error)
+}
+
+// subscriptionIDs assigns the stable, caller-facing ID of each Subscription, independently
+// of the server-assigned ID backing it on the wire.
+var subscriptionIDs atomic.Uint32
+
+// newSubscription creates a Subscription with its event queue configured from opts and
+// starts the goroutine that dispatches events to it in order.
+func newSubscription(opts SubscribeOptions, log *slog.Logger) *Subscription {
+	opts = opts.withDefaults()
+	sub := &Subscription{
+		ID:     subscriptionIDs.Add(1),
+		events: make(chan json.RawMessage, opts.QueueCapacity),
+		policy: opts.Overflow,
+		done:   make(chan struct{}),
+		log:    log,
+	}
+	go sub.dispatch()
+	return sub
+}
+
+// dispatch delivers queued events to the Subscription's current handler one at a time, in
+// the order they were enqueued, until the subscription is torn down.
+func (s *Subscription) dispatch() {
+	for {
+		select {
+		case payload := <-s.events:
+			s.handler().HandleEvent(payload)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// enqueue adds payload to the subscription's event queue, applying its OverflowPolicy if
+// the queue is already full. A payload already handed off for delivery recently is silently
+// dropped instead of being queued again; see Subscription.alreadyDelivered. A payload that
+// is itself dropped by the OverflowPolicy is not recorded, so a later resend of it is still
+// delivered.
+func (s *Subscription) enqueue(payload json.RawMessage) {
+	h := hashEvent(payload)
+	if s.alreadyDelivered(h) {
+		return
+	}
+	select {
+	case s.events <- payload:
+		s.markDelivered(h)
+		return
+	default:
+	}
+	switch s.policy {
+	case DropNewest:
+		s.dropped.Add(1)
+		s.log.Debug("dropped event, queue full", slog.Uint64("total_dropped", s.dropped.Load()))
+	case Disconnect:
+		s.teardown(ErrSubscriptionQueueOverflow)
+	default: // DropOldest
+		select {
+		case old := <-s.events:
+			s.dropped.Add(1)
+			// old is discarded without being handed off, so it is removed from the dedup window.
+			oh := hashEvent(old)
+			s.dedupMu.Lock()
+			for i, p := range s.recent {
+				if p == oh {
+					s.recent[i] = 0
+				}
+			}
+			s.dedupMu.Unlock()
+		default:
+		}
+		select {
+		case s.events <- payload:
+			s.markDelivered(h)
+		default:
+		}
+		s.log.Debug("dropped event, queue full", slog.Uint64("total_dropped", s.dropped.Load()))
+	}
+}
+
+// Dropped returns the number of events dropped from the subscription's queue so far under
+// OverflowPolicy DropOldest or DropNewest.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// teardown stops the subscription's dispatch goroutine and, if err is non-nil, surfaces it
+// to the subscription's handler. HandleError is called from its own goroutine, the same way
+// HandleEvent is, so a caller that reacts to it (for example by calling Conn.Unsubscribe)
+// never does so from a goroutine holding a lock teardown itself may have been called under.
+func (s *Subscription) teardown(err error) {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if err != nil {
+			if eh, ok := s.handler().(SubscriptionErrorHandler); ok {
+				go eh.HandleError(err)
+			}
+		}
+	})
+}
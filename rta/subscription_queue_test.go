@@ -0,0 +1,109 @@
+package rta
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingHandler struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (h *recordingHandler) HandleEvent(custom json.RawMessage) {
+	h.mu.Lock()
+	h.events = append(h.events, string(custom))
+	h.mu.Unlock()
+}
+
+func (h *recordingHandler) snapshot() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.events...)
+}
+
+// TestSubscriptionDispatchPreservesEnqueueOrder asserts that events handed to enqueue in a
+// given order are always delivered to the handler in that same order, regardless of how the
+// dispatch goroutine happens to be scheduled.
+func TestSubscriptionDispatchPreservesEnqueueOrder(t *testing.T) {
+	const n = 50
+	sub := newSubscription(SubscribeOptions{QueueCapacity: n}, slog.Default())
+	h := &recordingHandler{}
+	sub.Handle(h)
+
+	for i := 0; i < n; i++ {
+		sub.enqueue(json.RawMessage(fmt.Sprintf(`"%d"`, i)))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(h.snapshot()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d events, want %d", len(h.snapshot()), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	events := h.snapshot()
+	for i, got := range events {
+		if want := fmt.Sprintf(`"%d"`, i); got != want {
+			t.Fatalf("event %d out of order: got %s, want %s", i, got, want)
+		}
+	}
+}
+
+type errorRecordingHandler struct {
+	blocked chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func (h *errorRecordingHandler) HandleEvent(json.RawMessage) {
+	<-h.blocked
+}
+
+func (h *errorRecordingHandler) HandleError(err error) {
+	h.mu.Lock()
+	h.err = err
+	h.mu.Unlock()
+}
+
+func (h *errorRecordingHandler) snapshot() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// TestSubscriptionOverflowDisconnect asserts that a full queue under OverflowPolicy
+// Disconnect tears the subscription down and surfaces ErrSubscriptionQueueOverflow through
+// SubscriptionErrorHandler.
+func TestSubscriptionOverflowDisconnect(t *testing.T) {
+	h := &errorRecordingHandler{blocked: make(chan struct{})}
+	defer close(h.blocked)
+
+	sub := newSubscription(SubscribeOptions{QueueCapacity: 1, Overflow: Disconnect}, slog.Default())
+	sub.Handle(h)
+
+	sub.enqueue(json.RawMessage(`"first"`))
+	// Give the dispatch goroutine a chance to pick up "first" and block in HandleEvent,
+	// leaving the queue empty before it is filled below.
+	time.Sleep(10 * time.Millisecond)
+
+	sub.enqueue(json.RawMessage(`"second"`)) // fills the capacity-1 queue
+	sub.enqueue(json.RawMessage(`"third"`))  // queue full -> Disconnect
+
+	deadline := time.Now().Add(time.Second)
+	for h.snapshot() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected ErrSubscriptionQueueOverflow to be reported")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := h.snapshot(); err != ErrSubscriptionQueueOverflow {
+		t.Fatalf("got error %v, want %v", err, ErrSubscriptionQueueOverflow)
+	}
+}